@@ -0,0 +1,49 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambda/codec"
+)
+
+// Codec decodes invocation payloads and encodes handler responses, replacing the
+// hard-coded encoding/json path used by reflectHandler. See the lambda/codec
+// subpackage for the built-in JSON, Protobuf, and CBOR implementations.
+type Codec = codec.Codec
+
+// WithCodec replaces the JSON encoding/decoding reflectHandler otherwise uses with
+// codec. WithSetEscapeHTML and WithSetIndent have no effect once a codec is set; they
+// only configure the default JSON codec.
+//
+// Usage:
+//
+//	lambda.StartWithOptions(
+//		handler,
+//		lambda.WithCodec(&codec.ProtobufCodec{}),
+//	)
+func WithCodec(c Codec) Option {
+	return Option(func(h *handlerOptions) {
+		h.codec = c
+	})
+}
+
+// resolveCodec picks the codec reflectHandler should use for the current invocation:
+// the codec set by WithCodec, or else the default JSON codec built from the legacy
+// WithSetEscapeHTML/WithSetIndent options.
+//
+// Per-invocation content negotiation (choosing a codec from the invocation's
+// Content-Type) was dropped: nothing in this tree surfaces that header from the
+// runtime API into ctx, so a WithCodecs-style option would have been dead code. Add
+// it back once an invoke loop actually exposes the header.
+func resolveCodec(ctx context.Context, h *handlerOptions) Codec {
+	if h.codec != nil {
+		return h.codec
+	}
+	return &codec.JSONCodec{
+		EscapeHTML:   h.jsonResponseEscapeHTML,
+		IndentPrefix: h.jsonResponseIndentPrefix,
+		IndentValue:  h.jsonResponseIndentValue,
+	}
+}
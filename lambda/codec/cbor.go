@@ -0,0 +1,25 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package codec
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBORCodec decodes and encodes using the Concise Binary Object Representation
+// (RFC 8949).
+type CBORCodec struct{}
+
+func (c *CBORCodec) Decode(r io.Reader, v any) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
+
+func (c *CBORCodec) Encode(w io.Writer, v any) error {
+	return cbor.NewEncoder(w).Encode(v)
+}
+
+func (c *CBORCodec) ContentType() string {
+	return "application/cbor"
+}
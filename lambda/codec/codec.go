@@ -0,0 +1,21 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+// Package codec implements the wire formats a Lambda handler can use to decode
+// invocation payloads and encode responses, so that lambda.WithCodec is not limited
+// to encoding/json.
+package codec
+
+import "io"
+
+// Codec decodes an invocation payload into a handler's input type and encodes a
+// handler's return value into the bytes sent back to the runtime API.
+type Codec interface {
+	// Decode reads a single value from r into v.
+	Decode(r io.Reader, v any) error
+	// Encode writes v to w.
+	Encode(w io.Writer, v any) error
+	// ContentType is reported to the runtime API as the invocation response's
+	// Content-Type. It is not used to pick a codec per invocation: the codec set
+	// with lambda.WithCodec decodes every payload, whatever its Content-Type.
+	ContentType() string
+}
@@ -0,0 +1,10 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+//go:build !goccyjson
+
+package codec
+
+// GoJSONCodec falls back to the standard library's encoding/json when built
+// without the goccyjson tag, so importers don't pay for the
+// github.com/goccy/go-json dependency unless they opt in with -tags goccyjson.
+type GoJSONCodec = JSONCodec
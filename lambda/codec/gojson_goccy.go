@@ -0,0 +1,34 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+//go:build goccyjson
+
+package codec
+
+import (
+	"io"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// GoJSONCodec is a drop-in, faster replacement for JSONCodec backed by
+// github.com/goccy/go-json. Build with -tags goccyjson to select it.
+type GoJSONCodec struct {
+	EscapeHTML   bool
+	IndentPrefix string
+	IndentValue  string
+}
+
+func (c *GoJSONCodec) Decode(r io.Reader, v any) error {
+	return gojson.NewDecoder(r).Decode(v)
+}
+
+func (c *GoJSONCodec) Encode(w io.Writer, v any) error {
+	encoder := gojson.NewEncoder(w)
+	encoder.SetEscapeHTML(c.EscapeHTML)
+	encoder.SetIndent(c.IndentPrefix, c.IndentValue)
+	return encoder.Encode(v)
+}
+
+func (c *GoJSONCodec) ContentType() string {
+	return contentTypeJSON
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package codec
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const contentTypeJSON = "application/json"
+
+// JSONCodec is the default Codec, backed by encoding/json. It mirrors the knobs
+// lambda.WithSetEscapeHTML and lambda.WithSetIndent have always exposed.
+type JSONCodec struct {
+	EscapeHTML   bool
+	IndentPrefix string
+	IndentValue  string
+}
+
+func (c *JSONCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func (c *JSONCodec) Encode(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(c.EscapeHTML)
+	encoder.SetIndent(c.IndentPrefix, c.IndentValue)
+	return encoder.Encode(v)
+}
+
+func (c *JSONCodec) ContentType() string {
+	return contentTypeJSON
+}
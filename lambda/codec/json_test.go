@@ -0,0 +1,43 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	type message struct {
+		Name string `json:"name"`
+	}
+
+	c := &JSONCodec{}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, message{Name: "hello"}); err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+
+	var decoded message
+	if err := c.Decode(&buf, &decoded); err != nil {
+		t.Fatalf("Decode() returned error: %v", err)
+	}
+	if decoded.Name != "hello" {
+		t.Errorf("got Name %q, want %q", decoded.Name, "hello")
+	}
+
+	if got, want := c.ContentType(), "application/json"; got != want {
+		t.Errorf("ContentType() = %q, want %q", got, want)
+	}
+}
+
+func TestJSONCodecEscapeHTML(t *testing.T) {
+	c := &JSONCodec{EscapeHTML: false}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, "<b>"); err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+	if got, want := buf.String(), "\"<b>\"\n"; got != want {
+		t.Errorf("Encode() with EscapeHTML=false = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package codec
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufCodec decodes and encodes using protocol buffers. It requires the
+// handler's input and output types to implement proto.Message.
+type ProtobufCodec struct{}
+
+func (c *ProtobufCodec) Decode(r io.Reader, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		// reflectHandler hands Decode a *TIn, so a TIn that is itself a pointer to a
+		// generated message (e.g. *MyRequest) arrives as a pointer to a nil pointer:
+		// allocate the message it should point to and decode into that.
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr {
+			if rv.Elem().IsNil() {
+				rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+			}
+			msg, ok = rv.Elem().Interface().(proto.Message)
+		}
+		if !ok {
+			return fmt.Errorf("codec: %T does not implement proto.Message", v)
+		}
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, msg)
+}
+
+func (c *ProtobufCodec) Encode(w io.Writer, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		// generated proto.Message methods have a pointer receiver, but idiomatic
+		// handlers return their TOut by value (e.g. func(ctx, in) (MyResponse,
+		// error)), which arrives here as an unaddressable interface value. Copy it
+		// into a new pointer before giving up, mirroring how Decode is always
+		// handed a pointer by reflectHandler. A nil v (legal when TOut is any/
+		// interface{}) has no type to copy, so reject it the same way up front.
+		if v == nil {
+			return fmt.Errorf("codec: %T does not implement proto.Message", v)
+		}
+		ptr := reflect.New(reflect.TypeOf(v))
+		ptr.Elem().Set(reflect.ValueOf(v))
+		msg, ok = ptr.Interface().(proto.Message)
+		if !ok {
+			return fmt.Errorf("codec: %T does not implement proto.Message", v)
+		}
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (c *ProtobufCodec) ContentType() string {
+	return "application/protobuf"
+}
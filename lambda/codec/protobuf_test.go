@@ -0,0 +1,64 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtobufCodecRoundTripPointer(t *testing.T) {
+	c := &ProtobufCodec{}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, &wrapperspb.StringValue{Value: "hello"}); err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+
+	decoded := &wrapperspb.StringValue{}
+	if err := c.Decode(&buf, decoded); err != nil {
+		t.Fatalf("Decode() returned error: %v", err)
+	}
+	if decoded.Value != "hello" {
+		t.Errorf("got Value %q, want %q", decoded.Value, "hello")
+	}
+}
+
+// TestProtobufCodecEncodeValue guards against the pointer/value mismatch between
+// Decode (always handed a pointer by reflectHandler) and Encode (handed the
+// handler's return value as-is): an idiomatic handler returning a TOut by value,
+// e.g. func(ctx, in) (wrapperspb.StringValue, error), must still encode.
+func TestProtobufCodecEncodeValue(t *testing.T) {
+	c := &ProtobufCodec{}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, wrapperspb.StringValue{Value: "hello"}); err != nil {
+		t.Fatalf("Encode() of a non-pointer proto.Message returned error: %v", err)
+	}
+
+	decoded := &wrapperspb.StringValue{}
+	if err := c.Decode(&buf, decoded); err != nil {
+		t.Fatalf("Decode() returned error: %v", err)
+	}
+	if decoded.Value != "hello" {
+		t.Errorf("got Value %q, want %q", decoded.Value, "hello")
+	}
+}
+
+func TestProtobufCodecEncodeNonMessage(t *testing.T) {
+	c := &ProtobufCodec{}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, "not a proto message"); err == nil {
+		t.Fatal("Encode() of a non-proto.Message value should return an error")
+	}
+}
+
+// TestProtobufCodecEncodeNil guards against a nil v (legal when TOut is any/
+// interface{}) reaching reflect.New(nil) and panicking instead of erroring.
+func TestProtobufCodecEncodeNil(t *testing.T) {
+	c := &ProtobufCodec{}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, nil); err == nil {
+		t.Fatal("Encode() of a nil value should return an error, not panic")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import "context"
+
+type invocationContextKey struct{}
+
+type invocationMetadata struct {
+	awsRequestID       string
+	invokedFunctionArn string
+}
+
+func newInvocationContext(ctx context.Context, requestID, invokedFunctionArn string) context.Context {
+	return context.WithValue(ctx, invocationContextKey{}, invocationMetadata{
+		awsRequestID:       requestID,
+		invokedFunctionArn: invokedFunctionArn,
+	})
+}
+
+// NewInvocationContext returns a copy of ctx carrying requestID and
+// invokedFunctionArn as its invocation metadata, as AwsRequestID and
+// InvokedFunctionArn would report for a real invocation. It's exported for tests of
+// code that reads invocation metadata -- e.g. lambda/middleware -- that runs outside
+// the runtime API loop that normally attaches it.
+func NewInvocationContext(ctx context.Context, requestID, invokedFunctionArn string) context.Context {
+	return newInvocationContext(ctx, requestID, invokedFunctionArn)
+}
+
+func invocationFromContext(ctx context.Context) (invocationMetadata, bool) {
+	m, ok := ctx.Value(invocationContextKey{}).(invocationMetadata)
+	return m, ok
+}
+
+// AwsRequestID returns the AWS request ID of the invocation ctx belongs to, as
+// reported by the runtime API, and whether one was present.
+func AwsRequestID(ctx context.Context) (string, bool) {
+	m, ok := invocationFromContext(ctx)
+	return m.awsRequestID, ok
+}
+
+// InvokedFunctionArn returns the ARN the function was invoked as, and whether one was
+// present.
+func InvokedFunctionArn(ctx context.Context) (string, bool) {
+	m, ok := invocationFromContext(ctx)
+	return m.invokedFunctionArn, ok
+}
@@ -17,6 +17,10 @@ var (
 		env: "AWS_LAMBDA_RUNTIME_API",
 		f:   startRuntimeAPILoop,
 	}
+	runtimeAPIStreamingStartFunction = &startFunction{
+		env: "AWS_LAMBDA_RUNTIME_API",
+		f:   startRuntimeAPIStreamingLoop,
+	}
 	startFunctions = []*startFunction{runtimeAPIStartFunction}
 
 	// This allows end to end testing of the Start functions, by tests overwriting this function to keep the program alive
@@ -24,6 +28,11 @@ var (
 )
 
 func start(handler *handlerOptions) {
+	startFunctions := startFunctions
+	if handler.enableResponseStreaming && handler.streamingHandlerFunc != nil {
+		startFunctions = []*startFunction{runtimeAPIStreamingStartFunction}
+	}
+
 	var keys []string
 	for _, start := range startFunctions {
 		config := os.Getenv(start.env)
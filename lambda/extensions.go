@@ -0,0 +1,88 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda/extensions"
+)
+
+// ExtensionHandler processes a single event delivered to an internal extension.
+type ExtensionHandler func(ctx context.Context, event extensions.NextEvent) error
+
+type registeredExtension struct {
+	name    string
+	events  []extensions.EventType
+	handler ExtensionHandler
+}
+
+// WithExtension registers an internal Lambda Extension, alongside the handler, that
+// receives events from the Extensions API (https://docs.aws.amazon.com/lambda/latest/dg/runtimes-extensions-api.html).
+// name must be unique within the execution environment. The extension registers for
+// events and polls for them on its own goroutine for the lifetime of the process,
+// stopping once it has delivered a SHUTDOWN event to handler.
+//
+// Usage:
+//
+//	lambda.StartWithOptions(
+//		handler,
+//		lambda.WithExtension("telemetry-flush", []extensions.EventType{extensions.Invoke, extensions.Shutdown},
+//			func(ctx context.Context, event extensions.NextEvent) error {
+//				return flush(ctx)
+//			},
+//		),
+//	)
+func WithExtension(name string, events []extensions.EventType, handler ExtensionHandler) Option {
+	return Option(func(h *handlerOptions) {
+		h.extensions = append(h.extensions, registeredExtension{
+			name:    name,
+			events:  events,
+			handler: handler,
+		})
+	})
+}
+
+// startExtensions registers each extension configured via WithExtension and begins
+// polling for events on its own goroutine. ctx is passed through to each extension's
+// handler (it's cancelled on SIGTERM, mirroring ShutdownContext), but the long poll
+// itself is not tied to ctx: see pollExtensionEvents.
+func startExtensions(ctx context.Context, registered []registeredExtension) {
+	runtimeAPI := os.Getenv("AWS_LAMBDA_RUNTIME_API")
+	if runtimeAPI == "" || len(registered) == 0 {
+		return
+	}
+
+	for _, ext := range registered {
+		client := extensions.NewClient(runtimeAPI)
+		if err := client.Register(ctx, ext.name, ext.events); err != nil {
+			log.Printf("failed to register extension %q: %v", ext.name, err)
+			continue
+		}
+		go pollExtensionEvents(ctx, client, ext)
+	}
+}
+
+func pollExtensionEvents(ctx context.Context, client *extensions.Client, ext registeredExtension) {
+	for {
+		// The long poll deliberately does not use ctx: ctx is cancelled on SIGTERM,
+		// which races the runtime's SHUTDOWN event delivered as the response to this
+		// very in-flight request. Polling uncancelled lets that SHUTDOWN event (and
+		// its handler invocation below) always land before this goroutine exits.
+		event, err := client.NextEvent(context.Background())
+		if err != nil {
+			log.Printf("extension %q failed to get next event: %v", ext.name, err)
+			return
+		}
+
+		if err := ext.handler(ctx, *event); err != nil {
+			log.Printf("extension %q handler returned error for %s event: %v", ext.name, event.EventType, err)
+		}
+
+		if event.EventType == extensions.Shutdown {
+			return
+		}
+	}
+}
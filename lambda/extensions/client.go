@@ -0,0 +1,182 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+// Package extensions implements a minimal client for the AWS Lambda Extensions API,
+// allowing in-process extensions to register for INVOKE and SHUTDOWN events
+// alongside the handler running in the same execution environment.
+package extensions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	extensionNameHeader       = "Lambda-Extension-Name"
+	extensionIdentifierHeader = "Lambda-Extension-Identifier"
+	extensionErrorTypeHeader  = "Lambda-Extension-Function-Error-Type"
+
+	registerPath  = "/2020-01-01/extension/register"
+	nextEventPath = "/2020-01-01/extension/event/next"
+	initErrorPath = "/2020-01-01/extension/init/error"
+	exitErrorPath = "/2020-01-01/extension/exit/error"
+)
+
+// EventType identifies the kind of event an extension is registered to receive.
+type EventType string
+
+const (
+	// Invoke is sent once per function invocation.
+	Invoke EventType = "INVOKE"
+	// Shutdown is sent once when the execution environment is about to be shut down.
+	Shutdown EventType = "SHUTDOWN"
+)
+
+// Tracing describes the X-Ray tracing context attached to a NextEvent.
+type Tracing struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// NextEvent is the payload returned from a long poll against the event/next endpoint.
+type NextEvent struct {
+	EventType          EventType `json:"eventType"`
+	DeadlineMs         int64     `json:"deadlineMs"`
+	RequestID          string    `json:"requestId,omitempty"`
+	InvokedFunctionArn string    `json:"invokedFunctionArn,omitempty"`
+	ShutdownReason     string    `json:"shutdownReason,omitempty"`
+	Tracing            *Tracing  `json:"tracing,omitempty"`
+}
+
+// Client talks to the Extensions API exposed by the Lambda Runtime at runtimeAPI
+// (the value of the AWS_LAMBDA_RUNTIME_API environment variable).
+type Client struct {
+	runtimeAPI string
+	httpClient *http.Client
+
+	identifier string
+}
+
+// NewClient constructs a Client bound to the given runtime API address.
+func NewClient(runtimeAPI string) *Client {
+	return &Client{
+		runtimeAPI: runtimeAPI,
+		httpClient: &http.Client{},
+	}
+}
+
+// Register registers name as an internal extension interested in events, storing
+// the Lambda-Extension-Identifier assigned by the runtime on c so later requests
+// carry it. It must be called once,
+// before the first call to NextEvent.
+func (c *Client) Register(ctx context.Context, name string, events []EventType) error {
+	body, err := json.Marshal(struct {
+		Events []EventType `json:"events"`
+	}{Events: events})
+	if err != nil {
+		return fmt.Errorf("failed to marshal register request: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, registerPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(extensionNameHeader, name)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to register extension %q: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	identifier := resp.Header.Get(extensionIdentifierHeader)
+	if identifier == "" {
+		return fmt.Errorf("register extension %q: runtime API did not return %s", name, extensionIdentifierHeader)
+	}
+	c.identifier = identifier
+	return nil
+}
+
+// NextEvent long-polls the runtime for the next INVOKE or SHUTDOWN event. It blocks
+// until an event is available, the context is cancelled, or the runtime API errors.
+func (c *Client) NextEvent(ctx context.Context) (*NextEvent, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, nextEventPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var event NextEvent
+	if err := json.NewDecoder(resp.Body).Decode(&event); err != nil {
+		return nil, fmt.Errorf("failed to decode next event: %w", err)
+	}
+	return &event, nil
+}
+
+// InitError reports that the extension failed to initialize.
+func (c *Client) InitError(ctx context.Context, errorType, errorMessage string) error {
+	return c.postError(ctx, initErrorPath, errorType, errorMessage)
+}
+
+// ExitError reports that the extension encountered an unrecoverable error and is exiting.
+func (c *Client) ExitError(ctx context.Context, errorType, errorMessage string) error {
+	return c.postError(ctx, exitErrorPath, errorType, errorMessage)
+}
+
+func (c *Client) postError(ctx context.Context, path, errorType, errorMessage string) error {
+	body, err := json.Marshal(struct {
+		ErrorMessage string `json:"errorMessage"`
+		ErrorType    string `json:"errorType"`
+	}{ErrorMessage: errorMessage, ErrorType: errorType})
+	if err != nil {
+		return fmt.Errorf("failed to marshal error report: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(extensionErrorTypeHeader, errorType)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	url := fmt.Sprintf("http://%s%s", c.runtimeAPI, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %s: %w", path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.identifier != "" {
+		req.Header.Set(extensionIdentifierHeader, c.identifier)
+	}
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
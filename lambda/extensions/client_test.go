@@ -0,0 +1,112 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package extensions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientNextEventDecoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != nextEventPath {
+			t.Errorf("got path %q, want %q", r.URL.Path, nextEventPath)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NextEvent{
+			EventType:          Invoke,
+			DeadlineMs:         1700000000000,
+			RequestID:          "req-1",
+			InvokedFunctionArn: "arn:aws:lambda:us-east-1:123456789012:function:my-func",
+		})
+	}))
+	defer srv.Close()
+
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"))
+	event, err := c.NextEvent(context.Background())
+	if err != nil {
+		t.Fatalf("NextEvent() returned error: %v", err)
+	}
+	if event.EventType != Invoke {
+		t.Errorf("got EventType %q, want %q", event.EventType, Invoke)
+	}
+	if event.RequestID != "req-1" {
+		t.Errorf("got RequestID %q, want %q", event.RequestID, "req-1")
+	}
+	if event.DeadlineMs != 1700000000000 {
+		t.Errorf("got DeadlineMs %d, want %d", event.DeadlineMs, 1700000000000)
+	}
+}
+
+func TestClientNextEventShutdown(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NextEvent{
+			EventType:      Shutdown,
+			ShutdownReason: "spindown",
+		})
+	}))
+	defer srv.Close()
+
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"))
+	event, err := c.NextEvent(context.Background())
+	if err != nil {
+		t.Fatalf("NextEvent() returned error: %v", err)
+	}
+	if event.EventType != Shutdown {
+		t.Errorf("got EventType %q, want %q", event.EventType, Shutdown)
+	}
+	if event.ShutdownReason != "spindown" {
+		t.Errorf("got ShutdownReason %q, want %q", event.ShutdownReason, "spindown")
+	}
+}
+
+func TestClientRegisterSetsIdentifier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != registerPath {
+			t.Errorf("got path %q, want %q", r.URL.Path, registerPath)
+		}
+		if got := r.Header.Get(extensionNameHeader); got != "my-extension" {
+			t.Errorf("got %s %q, want %q", extensionNameHeader, got, "my-extension")
+		}
+		w.Header().Set(extensionIdentifierHeader, "ext-id-1")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"))
+	if err := c.Register(context.Background(), "my-extension", []EventType{Invoke, Shutdown}); err != nil {
+		t.Fatalf("Register() returned error: %v", err)
+	}
+	if c.identifier != "ext-id-1" {
+		t.Errorf("got identifier %q, want %q", c.identifier, "ext-id-1")
+	}
+
+	// subsequent requests should carry the identifier the runtime assigned.
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(extensionIdentifierHeader); got != "ext-id-1" {
+			t.Errorf("got %s %q, want %q", extensionIdentifierHeader, got, "ext-id-1")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NextEvent{EventType: Shutdown})
+	})
+	if _, err := c.NextEvent(context.Background()); err != nil {
+		t.Fatalf("NextEvent() returned error: %v", err)
+	}
+}
+
+func TestClientErrorStatusReturnsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"))
+	if err := c.Register(context.Background(), "my-extension", []EventType{Invoke}); err == nil {
+		t.Fatal("Register() with a 500 response should return an error")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda/extensions"
+)
+
+func TestPollExtensionEventsSurvivesCtxCancelDuringLongPoll(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/2020-01-01/extension/register" {
+			w.Header().Set("Lambda-Extension-Identifier", "ext-id-1")
+			return
+		}
+		<-release // simulate the runtime holding the long poll open until SIGTERM fires
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(extensions.NextEvent{
+			EventType:      extensions.Shutdown,
+			ShutdownReason: "spindown",
+		})
+	}))
+	defer srv.Close()
+
+	client := extensions.NewClient(strings.TrimPrefix(srv.URL, "http://"))
+	if err := client.Register(context.Background(), "my-extension", []extensions.EventType{extensions.Shutdown}); err != nil {
+		t.Fatalf("Register() returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var gotEvent *extensions.NextEvent
+	done := make(chan struct{})
+	go func() {
+		pollExtensionEvents(ctx, client, registeredExtension{
+			name: "my-extension",
+			handler: func(_ context.Context, event extensions.NextEvent) error {
+				mu.Lock()
+				gotEvent = &event
+				mu.Unlock()
+				return nil
+			},
+		})
+		close(done)
+	}()
+
+	// give the long poll time to be in flight, then cancel as SIGTERM would.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pollExtensionEvents() did not return after delivering the SHUTDOWN event")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotEvent == nil {
+		t.Fatal("extension handler was never invoked with the SHUTDOWN event")
+	}
+	if gotEvent.EventType != extensions.Shutdown {
+		t.Errorf("got EventType %q, want %q", gotEvent.EventType, extensions.Shutdown)
+	}
+	if gotEvent.ShutdownReason != "spindown" {
+		t.Errorf("got ShutdownReason %q, want %q", gotEvent.ShutdownReason, "spindown")
+	}
+}
@@ -5,11 +5,12 @@ package lambda
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil" // nolint:staticcheck
+	"time"
 
+	"github.com/aws/aws-lambda-go/lambda/codec"
 	"github.com/aws/aws-lambda-go/lambda/handlertrace"
 )
 
@@ -24,7 +25,15 @@ type handlerOptions struct {
 	jsonResponseIndentPrefix string
 	jsonResponseIndentValue  string
 	enableSIGTERM            bool
-	sigtermCallbacks         []func()
+	sigtermCallbacks         []func(context.Context)
+	shutdownTimeout          time.Duration
+	onShutdown               func(context.Context) error
+	extensions               []registeredExtension
+	enableResponseStreaming  bool
+	streamingHandlerFunc     streamingHandlerFunc
+	codec                    Codec
+	middlewares              []Middleware
+	cancelShutdown           context.CancelFunc
 }
 
 type Option func(*handlerOptions)
@@ -80,7 +89,9 @@ func WithSetIndent(prefix, indent string) Option {
 
 // WithEnableSIGTERM enables SIGTERM behavior within the Lambda platform on container spindown.
 // SIGKILL will occur ~500ms after SIGTERM.
-// Optionally, an array of callback functions to run on SIGTERM may be provided.
+// Optionally, an array of callback functions to run on SIGTERM may be provided. Each
+// callback is passed the context returned by ShutdownContext, which is cancelled the
+// moment SIGTERM arrives.
 //
 // Usage:
 //
@@ -88,18 +99,18 @@ func WithSetIndent(prefix, indent string) Option {
 //	    func (event any) (any, error) {
 //			return event, nil
 //		},
-//		lambda.WithEnableSIGTERM(func() {
+//		lambda.WithEnableSIGTERM(func(ctx context.Context) {
 //			log.Print("function container shutting down...")
 //		})
 //	)
-func WithEnableSIGTERM(callbacks ...func()) Option {
+func WithEnableSIGTERM(callbacks ...func(context.Context)) Option {
 	return Option(func(h *handlerOptions) {
 		h.sigtermCallbacks = append(h.sigtermCallbacks, callbacks...)
 		h.enableSIGTERM = true
 	})
 }
 
-func newHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](handlerFunc H, options ...Option) *handlerOptions {
+func newHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](fn H, options ...Option) *handlerOptions {
 	h := &handlerOptions{
 		baseContext:              context.Background(),
 		jsonResponseEscapeHTML:   false,
@@ -109,10 +120,53 @@ func newHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](handlerFunc H, opti
 	for _, option := range options {
 		option(h)
 	}
+
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+	h.baseContext = context.WithValue(h.baseContext, shutdownContextKey{}, shutdownCtx)
+	// stored on h rather than deferred here: it must stay live for the lifetime of
+	// the handler, and is called from runOnShutdown once the process is actually
+	// tearing down (it's also in sigtermCallbacks below when SIGTERM handling is
+	// enabled; context.CancelFunc is idempotent, so calling it from both is safe).
+	h.cancelShutdown = cancelShutdown
+
+	if len(h.extensions) > 0 {
+		h.enableSIGTERM = true
+		startExtensions(shutdownCtx, h.extensions)
+	}
 	if h.enableSIGTERM {
-		enableSIGTERM(h.sigtermCallbacks)
+		callbacks := make([]func(), 0, len(h.sigtermCallbacks)+1)
+		// cancel first, so every callback is handed an already-cancelled shutdownCtx.
+		callbacks = append(callbacks, cancelShutdown)
+		for _, cb := range h.sigtermCallbacks {
+			cb := cb
+			callbacks = append(callbacks, func() { cb(shutdownCtx) })
+		}
+		enableSIGTERM(callbacks)
+	}
+
+	h.handlerFunc = reflectHandler(fn, h)
+	if len(h.middlewares) > 0 {
+		inner := h.handlerFunc
+		contentType := contentTypeJSON
+		tracked := handlerFunc(func(ctx context.Context, payload []byte) (io.Reader, error) {
+			response, err := inner(ctx, payload)
+			if err != nil {
+				return nil, err
+			}
+			if ct, ok := response.(contentTyper); ok {
+				contentType = ct.ContentType()
+			}
+			return response, nil
+		})
+		wrapped := chainMiddleware(tracked, h.middlewares)
+		h.handlerFunc = func(ctx context.Context, payload []byte) (io.Reader, error) {
+			response, err := wrapped.Invoke(ctx, payload)
+			if err != nil {
+				return nil, err
+			}
+			return &contentTypedBuffer{Buffer: bytes.NewBuffer(response), contentType: contentType}, nil
+		}
 	}
-	h.handlerFunc = reflectHandler(handlerFunc, h)
 	return h
 }
 
@@ -150,30 +204,44 @@ func errorHandler(err error) handlerFunc {
 
 type jsonOutBuffer struct {
 	*bytes.Buffer
+	contentType string
 }
 
 func (j *jsonOutBuffer) ContentType() string {
+	if j.contentType != "" {
+		return j.contentType
+	}
 	return contentTypeJSON
 }
 
+// contentTypedBuffer carries the content type resolved for a response through the
+// WithMiddleware chain, which otherwise only sees raw bytes: see the tracked
+// handlerFunc built in newHandler.
+type contentTypedBuffer struct {
+	*bytes.Buffer
+	contentType string
+}
+
+func (c *contentTypedBuffer) ContentType() string {
+	return c.contentType
+}
+
 func reflectHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](f H, h *handlerOptions) handlerFunc {
 	if f == nil {
 		return errorHandler(errors.New("handler is nil"))
 	}
 
-	out := &jsonOutBuffer{bytes.NewBuffer(nil)}
+	out := &jsonOutBuffer{Buffer: bytes.NewBuffer(nil)}
 	return func(ctx context.Context, payload []byte) (io.Reader, error) {
 		out.Reset()
 		in := bytes.NewBuffer(payload)
-		decoder := json.NewDecoder(in)
-		encoder := json.NewEncoder(out)
-		encoder.SetEscapeHTML(h.jsonResponseEscapeHTML)
-		encoder.SetIndent(h.jsonResponseIndentPrefix, h.jsonResponseIndentValue)
+		c := resolveCodec(ctx, h)
+		out.contentType = c.ContentType()
 
 		trace := handlertrace.FromContext(ctx)
 
 		event := new(TIn)
-		if err := decoder.Decode(event); err != nil {
+		if err := c.Decode(in, event); err != nil {
 			return nil, err
 		}
 		if nil != trace.RequestEvent {
@@ -189,13 +257,13 @@ func reflectHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](f H, h *handler
 			trace.ResponseEvent(ctx, response)
 		}
 
-		// encode to JSON
-		if err := encoder.Encode(response); err != nil {
+		if err := c.Encode(out, response); err != nil {
 			return nil, err
 		}
 
-		// back-compat, strip the encoder's trailing newline unless WithSetIndent was used
-		if h.jsonResponseIndentValue == "" && h.jsonResponseIndentPrefix == "" {
+		// back-compat: the default JSON codec's encoder appends a trailing newline;
+		// strip it unless WithSetIndent was used
+		if jc, ok := c.(*codec.JSONCodec); ok && jc.IndentValue == "" && jc.IndentPrefix == "" {
 			out.Truncate(out.Len() - 1)
 		}
 		return out, nil
@@ -0,0 +1,118 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// contentTyper is implemented by jsonOutBuffer, and by contentTypedBuffer for
+// responses that have passed through a WithMiddleware chain, so the invoke loop can
+// report the resolved Codec's content type on the wire. A response implementing
+// neither falls back to contentTypeJSON.
+type contentTyper interface {
+	ContentType() string
+}
+
+// startRuntimeAPILoop is the buffered invoke loop used by Start and StartWithOptions:
+// it reads events from, and posts whole responses back to, the AWS Lambda Runtime
+// API. See startRuntimeAPIStreamingLoop for the response-streaming counterpart.
+func startRuntimeAPILoop(api string, h *handlerOptions) error {
+	shutdownCtx := ShutdownContext(h.baseContext)
+	client := &http.Client{}
+	for {
+		select {
+		case <-shutdownCtx.Done():
+			// SIGTERM arrived before or during the invocation just handled: stop
+			// pulling new work and drain.
+			runOnShutdown(h)
+			return nil
+		default:
+		}
+
+		requestID, deadline, invokedFunctionArn, payload, err := getNextInvocation(client, api)
+		if err != nil {
+			return fmt.Errorf("failed to get next invocation: %w", err)
+		}
+
+		if err := handleInvocation(h, client, api, requestID, deadline, invokedFunctionArn, payload); err != nil {
+			return err
+		}
+	}
+}
+
+// handleInvocation runs a single invocation in its own call frame so the deadline
+// context's cancel func, if any, runs as soon as the invocation finishes instead of
+// piling up on startRuntimeAPILoop's defer stack for the life of the warm container.
+func handleInvocation(h *handlerOptions, client *http.Client, api, requestID string, deadline *time.Time, invokedFunctionArn string, payload []byte) error {
+	ctx := h.baseContext
+	if deadline != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, *deadline)
+		defer cancel()
+	}
+	ctx = newInvocationContext(ctx, requestID, invokedFunctionArn)
+
+	response, invokeErr := h.handlerFunc(ctx, payload)
+	if invokeErr == nil {
+		invokeErr = postInvocationResponse(client, api, requestID, response)
+	}
+	if invokeErr != nil {
+		if err := postInvocationError(client, api, requestID, invokeErr); err != nil {
+			return fmt.Errorf("failed to post invocation error: %w", err)
+		}
+	}
+	return nil
+}
+
+func postInvocationResponse(client *http.Client, api, requestID string, response io.Reader) error {
+	if response, ok := response.(io.Closer); ok {
+		defer response.Close()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+api+fmt.Sprintf(invocationRespPath, requestID), response)
+	if err != nil {
+		return err
+	}
+	contentType := contentTypeJSON
+	if ct, ok := response.(contentTyper); ok {
+		contentType = ct.ContentType()
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+func postInvocationError(client *http.Client, api, requestID string, invokeErr error) error {
+	body, err := json.Marshal(struct {
+		ErrorMessage string `json:"errorMessage"`
+		ErrorType    string `json:"errorType"`
+	}{ErrorMessage: invokeErr.Error(), ErrorType: "HandlerError"})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+api+fmt.Sprintf(invocationErrorPath, requestID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHandleInvocationReleasesDeadlineContextPromptly guards against the deadline
+// context's cancel func being deferred to startRuntimeAPILoop's return instead of
+// running at the end of the invocation that created it: in the warm-container loop,
+// the latter never returns, so every invocation's cancel func and timer would pile up
+// for the life of the container instead of being released as each invocation finishes.
+func TestHandleInvocationReleasesDeadlineContextPromptly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	api := strings.TrimPrefix(srv.URL, "http://")
+
+	var gotCtx context.Context
+	h := &handlerOptions{
+		baseContext: context.Background(),
+		handlerFunc: handlerFunc(func(ctx context.Context, payload []byte) (io.Reader, error) {
+			gotCtx = ctx
+			return bytes.NewReader(nil), nil
+		}),
+	}
+
+	deadline := time.Now().Add(time.Minute)
+	if err := handleInvocation(h, &http.Client{}, api, "req-1", &deadline, "arn", nil); err != nil {
+		t.Fatalf("handleInvocation() returned error: %v", err)
+	}
+
+	if gotCtx.Err() != context.Canceled {
+		t.Errorf("ctx.Err() = %v once handleInvocation returned, want context.Canceled: the deadline context's cancel func must run at the end of each invocation, not accumulate for the container's lifetime", gotCtx.Err())
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import "context"
+
+// Middleware wraps a Handler to add cross-cutting behavior -- auth, panic recovery,
+// metrics, retries, per-invoke logging -- without reaching into reflectHandler.
+// Middlewares run in the order passed to WithMiddleware: the first Middleware given
+// is outermost and sees the invocation first. See the lambda/middleware subpackage
+// for standard implementations.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware wraps the handler's final Handler with the given middlewares before
+// it's stored on handlerOptions, outermost first.
+//
+// Usage:
+//
+//	lambda.StartWithOptions(
+//		handler,
+//		lambda.WithMiddleware(middleware.Recover(), middleware.Timeout(100*time.Millisecond)),
+//	)
+func WithMiddleware(mws ...Middleware) Option {
+	return Option(func(h *handlerOptions) {
+		h.middlewares = append(h.middlewares, mws...)
+	})
+}
+
+func chainMiddleware(h Handler, mws []Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+type handlerFuncBytes func(ctx context.Context, payload []byte) ([]byte, error)
+
+func (f handlerFuncBytes) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return f(ctx, payload)
+}
+
+// Tap is a Middleware offering the same request/response observation hooks the
+// handlertrace package provides, without needing a context.Context plumbed in ahead
+// of time via handlertrace.NewContext: onRequest and onResponse are called with the
+// raw invocation payload and response bytes, before and after the wrapped Handler
+// runs respectively; onResponse is not called when the wrapped Handler returns an
+// error. Either may be nil. handlertrace itself is unchanged -- switch to
+// Tap at your own pace.
+func Tap(onRequest, onResponse func(ctx context.Context, payload []byte)) Middleware {
+	return func(next Handler) Handler {
+		return handlerFuncBytes(func(ctx context.Context, payload []byte) ([]byte, error) {
+			if onRequest != nil {
+				onRequest(ctx, payload)
+			}
+			response, err := next.Invoke(ctx, payload)
+			if err == nil && onResponse != nil {
+				onResponse(ctx, response)
+			}
+			return response, err
+		})
+	}
+}
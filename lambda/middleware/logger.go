@@ -0,0 +1,47 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+type loggerContextKey struct{}
+
+// FromContext returns the *slog.Logger Logger injected into ctx, or slog.Default()
+// if none was injected (for example, when called outside a handler invocation).
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Logger injects a request-scoped *slog.Logger, built from handler, into the
+// invocation context, annotated with aws_request_id and function_arn attributes.
+// Retrieve it inside the handler with FromContext.
+func Logger(handler slog.Handler) lambda.Middleware {
+	return func(next lambda.Handler) lambda.Handler {
+		return loggerHandler{next, slog.New(handler)}
+	}
+}
+
+type loggerHandler struct {
+	next   lambda.Handler
+	logger *slog.Logger
+}
+
+func (l loggerHandler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	logger := l.logger
+	if requestID, ok := lambda.AwsRequestID(ctx); ok {
+		logger = logger.With("aws_request_id", requestID)
+	}
+	if functionArn, ok := lambda.InvokedFunctionArn(ctx); ok {
+		logger = logger.With("function_arn", functionArn)
+	}
+	ctx = context.WithValue(ctx, loggerContextKey{}, logger)
+	return l.next.Invoke(ctx, payload)
+}
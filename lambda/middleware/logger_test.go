@@ -0,0 +1,67 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func TestLoggerInjectsRequestScopedLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, nil)
+
+	var loggedFromCtx bool
+	next := handlerFunc(func(ctx context.Context, _ []byte) ([]byte, error) {
+		loggedFromCtx = FromContext(ctx) != slog.Default()
+		FromContext(ctx).Info("handled")
+		return nil, nil
+	})
+
+	ctx := lambda.NewInvocationContext(context.Background(), "req-1", "arn:aws:lambda:us-east-1:123:function:f")
+	if _, err := Logger(handler)(next).Invoke(ctx, nil); err != nil {
+		t.Fatalf("Invoke() returned error: %v", err)
+	}
+
+	if !loggedFromCtx {
+		t.Error("FromContext(ctx) inside the handler should return the injected logger, not slog.Default()")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "aws_request_id=req-1") {
+		t.Errorf("log output %q does not contain aws_request_id attribute", out)
+	}
+	if !strings.Contains(out, "function_arn=arn:aws:lambda:us-east-1:123:function:f") {
+		t.Errorf("log output %q does not contain function_arn attribute", out)
+	}
+}
+
+func TestLoggerWithoutInvocationMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, nil)
+
+	next := handlerFunc(func(ctx context.Context, _ []byte) ([]byte, error) {
+		FromContext(ctx).Info("handled")
+		return nil, nil
+	})
+
+	if _, err := Logger(handler)(next).Invoke(context.Background(), nil); err != nil {
+		t.Fatalf("Invoke() returned error: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "aws_request_id") || strings.Contains(out, "function_arn") {
+		t.Errorf("log output %q should not carry invocation attributes when none were present", out)
+	}
+}
+
+func TestFromContextDefaultsWithoutLogger(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext() should return slog.Default() when no logger was injected")
+	}
+}
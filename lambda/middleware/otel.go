@@ -0,0 +1,46 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// OTel starts a span for each invocation using tracer, as a child of whatever span
+// ctx already carries. If the _X_AMZN_TRACE_ID environment variable is set, its value
+// is recorded on the span as the aws.xray.trace_id attribute; the span is not joined
+// to that X-Ray trace as its parent.
+func OTel(tracer trace.Tracer) lambda.Middleware {
+	return func(next lambda.Handler) lambda.Handler {
+		return otelHandler{next, tracer}
+	}
+}
+
+type otelHandler struct {
+	next   lambda.Handler
+	tracer trace.Tracer
+}
+
+func (o otelHandler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	ctx, span := o.tracer.Start(ctx, "lambda.Invoke")
+	defer span.End()
+
+	if traceID := os.Getenv("_X_AMZN_TRACE_ID"); traceID != "" {
+		span.SetAttributes(attribute.String("aws.xray.trace_id", traceID))
+	}
+	if requestID, ok := lambda.AwsRequestID(ctx); ok {
+		span.SetAttributes(attribute.String("faas.invocation_id", requestID))
+	}
+
+	response, err := o.next.Invoke(ctx, payload)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return response, err
+}
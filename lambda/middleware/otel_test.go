@@ -0,0 +1,97 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// recordingTracer is a minimal trace.Tracer that records the span started for the
+// single invocation under test, rather than pulling in the otel SDK just to assert
+// OTel's behavior.
+type recordingTracer struct {
+	noop.Tracer
+	span *recordingSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	r.span = &recordingSpan{}
+	return ctx, r.span
+}
+
+type recordingSpan struct {
+	noop.Span
+	attrs []attribute.KeyValue
+	err   error
+	ended bool
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordingSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.err = err
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func (s *recordingSpan) attr(key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range s.attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestOTelRecordsInvocationIDAndEndsSpan(t *testing.T) {
+	tracer := &recordingTracer{}
+	next := handlerFunc(func(ctx context.Context, _ []byte) ([]byte, error) {
+		return nil, nil
+	})
+
+	ctx := lambda.NewInvocationContext(context.Background(), "req-1", "arn:aws:lambda:us-east-1:123:function:f")
+	if _, err := OTel(tracer)(next).Invoke(ctx, nil); err != nil {
+		t.Fatalf("Invoke() returned error: %v", err)
+	}
+
+	if tracer.span == nil {
+		t.Fatal("OTel() did not start a span")
+	}
+	if v, ok := tracer.span.attr("faas.invocation_id"); !ok || v.AsString() != "req-1" {
+		t.Errorf("got faas.invocation_id attribute %v, %v, want \"req-1\", true", v, ok)
+	}
+	if !tracer.span.ended {
+		t.Error("OTel() did not end the span")
+	}
+	if tracer.span.err != nil {
+		t.Errorf("span recorded error %v for a successful invocation", tracer.span.err)
+	}
+}
+
+func TestOTelRecordsHandlerError(t *testing.T) {
+	tracer := &recordingTracer{}
+	wantErr := errors.New("boom")
+	next := handlerFunc(func(ctx context.Context, _ []byte) ([]byte, error) {
+		return nil, wantErr
+	})
+
+	_, err := OTel(tracer)(next).Invoke(context.Background(), nil)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Invoke() returned %v, want %v", err, wantErr)
+	}
+	if tracer.span.err != wantErr {
+		t.Errorf("span recorded error %v, want %v", tracer.span.err, wantErr)
+	}
+}
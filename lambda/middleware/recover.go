@@ -0,0 +1,35 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+// Package middleware provides standard lambda.Middleware implementations: panic
+// recovery, deadline-aware timeouts, request-scoped structured logging, and OpenTelemetry
+// tracing.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// Recover converts a panic anywhere in the handler chain below it into an error,
+// annotated with a stack trace, instead of letting it crash the process.
+func Recover() lambda.Middleware {
+	return func(next lambda.Handler) lambda.Handler {
+		return recoverHandler{next}
+	}
+}
+
+type recoverHandler struct {
+	next lambda.Handler
+}
+
+func (r recoverHandler) Invoke(ctx context.Context, payload []byte) (response []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic in handler: %v\n%s", p, debug.Stack())
+		}
+	}()
+	return r.next.Invoke(ctx, payload)
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+type handlerFunc func(ctx context.Context, payload []byte) ([]byte, error)
+
+func (f handlerFunc) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	return f(ctx, payload)
+}
+
+func TestRecoverConvertsPanicToError(t *testing.T) {
+	next := handlerFunc(func(_ context.Context, _ []byte) ([]byte, error) {
+		panic("boom")
+	})
+
+	_, err := Recover()(next).Invoke(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("error %q does not mention the panic value", err)
+	}
+}
+
+func TestMiddlewareOrdering(t *testing.T) {
+	var calls []string
+	mark := func(name string) lambda.Middleware {
+		return func(next lambda.Handler) lambda.Handler {
+			return handlerFunc(func(ctx context.Context, payload []byte) ([]byte, error) {
+				calls = append(calls, name+":before")
+				response, err := next.Invoke(ctx, payload)
+				calls = append(calls, name+":after")
+				return response, err
+			})
+		}
+	}
+
+	next := handlerFunc(func(_ context.Context, _ []byte) ([]byte, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	})
+
+	h := next
+	for _, mw := range []lambda.Middleware{mark("inner"), mark("outer")} {
+		h = mw(h).(handlerFunc)
+	}
+	if _, err := h.Invoke(context.Background(), nil); err != nil {
+		t.Fatalf("Invoke() returned error: %v", err)
+	}
+
+	want := []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}
+	if len(calls) != len(want) {
+		t.Fatalf("got calls %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
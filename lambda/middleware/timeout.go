@@ -0,0 +1,35 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// Timeout derives a context deadline margin earlier than the deadline the
+// runtime API reported for this invocation (via Lambda-Runtime-Deadline-Ms), so the
+// handler has time to clean up and return a response before the runtime kills it for
+// running out of time. If the invocation context carries no deadline, next runs
+// unmodified.
+func Timeout(margin time.Duration) lambda.Middleware {
+	return func(next lambda.Handler) lambda.Handler {
+		return timeoutHandler{next, margin}
+	}
+}
+
+type timeoutHandler struct {
+	next   lambda.Handler
+	margin time.Duration
+}
+
+func (t timeoutHandler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-t.margin))
+		defer cancel()
+	}
+	return t.next.Invoke(ctx, payload)
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutAppliesMargin(t *testing.T) {
+	deadline := time.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var gotDeadline time.Time
+	next := handlerFunc(func(ctx context.Context, _ []byte) ([]byte, error) {
+		gotDeadline, _ = ctx.Deadline()
+		return nil, nil
+	})
+
+	if _, err := Timeout(100*time.Millisecond)(next).Invoke(ctx, nil); err != nil {
+		t.Fatalf("Invoke() returned error: %v", err)
+	}
+
+	want := deadline.Add(-100 * time.Millisecond)
+	if !gotDeadline.Equal(want) {
+		t.Errorf("got deadline %v, want %v", gotDeadline, want)
+	}
+}
+
+func TestTimeoutNoDeadline(t *testing.T) {
+	var sawDeadline bool
+	next := handlerFunc(func(ctx context.Context, _ []byte) ([]byte, error) {
+		_, sawDeadline = ctx.Deadline()
+		return nil, nil
+	})
+
+	if _, err := Timeout(100*time.Millisecond)(next).Invoke(context.Background(), nil); err != nil {
+		t.Fatalf("Invoke() returned error: %v", err)
+	}
+
+	if sawDeadline {
+		t.Error("Invoke() should not introduce a deadline when ctx had none")
+	}
+}
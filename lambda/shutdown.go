@@ -0,0 +1,67 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type shutdownContextKey struct{}
+
+// ShutdownContext returns the context.Context that is cancelled once the function
+// container receives SIGTERM, derived from ctx (normally the context passed into a
+// handler). Unlike the per-invocation context, it is not cancelled when an individual
+// invocation's deadline passes, so it can be used to observe shutdown mid-invoke
+// without racing the invocation's own timeout. If ctx was not derived from a handler
+// started with WithEnableSIGTERM, WithOnShutdown, or WithExtension, the returned
+// context is never cancelled.
+func ShutdownContext(ctx context.Context) context.Context {
+	if shutdownCtx, ok := ctx.Value(shutdownContextKey{}).(context.Context); ok {
+		return shutdownCtx
+	}
+	return context.Background()
+}
+
+// WithShutdownTimeout bounds how long WithOnShutdown's callback is given to complete
+// once SIGTERM is received before the process exits. It does not bound how long the
+// invoke loop waits for the current in-flight invocation to finish draining; that
+// invocation is still only bounded by its own Lambda-Runtime-Deadline-Ms.
+func WithShutdownTimeout(d time.Duration) Option {
+	return Option(func(h *handlerOptions) {
+		h.shutdownTimeout = d
+	})
+}
+
+// WithOnShutdown registers a callback that runs after the last in-flight invocation
+// returns, but before the process exits, so callers can flush OpenTelemetry batches,
+// close database pools, or report a final Extensions API exit/error. It implies
+// WithEnableSIGTERM. The callback's context is bounded by WithShutdownTimeout, if set.
+func WithOnShutdown(fn func(ctx context.Context) error) Option {
+	return Option(func(h *handlerOptions) {
+		h.onShutdown = fn
+		h.enableSIGTERM = true
+	})
+}
+
+// runOnShutdown invokes h.onShutdown, if one was registered, bounding it by
+// h.shutdownTimeout when set, and releases the shutdown context's resources.
+func runOnShutdown(h *handlerOptions) {
+	if h.cancelShutdown != nil {
+		defer h.cancelShutdown()
+	}
+	if h.onShutdown == nil {
+		return
+	}
+
+	ctx := context.Background()
+	if h.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.shutdownTimeout)
+		defer cancel()
+	}
+	if err := h.onShutdown(ctx); err != nil {
+		log.Printf("onShutdown callback returned error: %v", err)
+	}
+}
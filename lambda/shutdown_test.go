@@ -0,0 +1,56 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"context"
+	"testing"
+)
+
+func TestShutdownContextUncancelledByDefault(t *testing.T) {
+	ctx := ShutdownContext(context.Background())
+	select {
+	case <-ctx.Done():
+		t.Fatal("ShutdownContext() of a context with no shutdown context attached should never be cancelled")
+	default:
+	}
+}
+
+func TestShutdownContextCancellation(t *testing.T) {
+	shutdownCtx, cancel := context.WithCancel(context.Background())
+	ctx := context.WithValue(context.Background(), shutdownContextKey{}, shutdownCtx)
+
+	got := ShutdownContext(ctx)
+	select {
+	case <-got.Done():
+		t.Fatal("ShutdownContext() reported cancelled before SIGTERM")
+	default:
+	}
+
+	cancel()
+
+	select {
+	case <-got.Done():
+	default:
+		t.Fatal("ShutdownContext() did not observe cancellation of the underlying shutdown context")
+	}
+}
+
+func TestRunOnShutdownInvokesCallback(t *testing.T) {
+	called := false
+	h := &handlerOptions{
+		onShutdown: func(ctx context.Context) error {
+			called = true
+			return nil
+		},
+	}
+	runOnShutdown(h)
+	if !called {
+		t.Error("runOnShutdown() did not invoke the registered onShutdown callback")
+	}
+}
+
+func TestRunOnShutdownNoCallback(t *testing.T) {
+	// must not panic when no onShutdown callback was registered.
+	runOnShutdown(&handlerOptions{})
+}
@@ -0,0 +1,373 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda/handlertrace"
+)
+
+const (
+	headerResponseMode       = "Lambda-Runtime-Function-Response-Mode"
+	headerAwsRequestID       = "Lambda-Runtime-Aws-Request-Id"
+	headerDeadlineMs         = "Lambda-Runtime-Deadline-Ms"
+	headerInvokedFunctionArn = "Lambda-Runtime-Invoked-Function-Arn"
+	responseModeStreaming    = "streaming"
+
+	invocationNextPath  = "/2018-06-01/runtime/invocation/next"
+	invocationRespPath  = "/2018-06-01/runtime/invocation/%s/response"
+	invocationErrorPath = "/2018-06-01/runtime/invocation/%s/error"
+)
+
+// StreamingResponseWriter is handed to a ResponseStreamer so it can write its response
+// body incrementally, rather than buffering it in memory before returning.
+type StreamingResponseWriter interface {
+	io.Writer
+
+	// SetContentType sets the Content-Type reported to the runtime API. It must be
+	// called, if at all, before the first call to Write.
+	SetContentType(string)
+}
+
+// ResponseStreamer is implemented by handler responses that want to stream their body
+// to the Lambda Runtime API as it's produced, instead of being buffered and JSON
+// encoded like a normal response.
+type ResponseStreamer interface {
+	Stream(ctx context.Context, w StreamingResponseWriter) error
+}
+
+// WithStreamingResponse enables response streaming mode: the handler's return value is
+// written to the runtime API incrementally over a chunked HTTP response instead of
+// being buffered in full before the invocation completes. It is only meaningful when
+// used with StartStreamingHandler.
+func WithStreamingResponse() Option {
+	return Option(func(h *handlerOptions) {
+		h.enableResponseStreaming = true
+	})
+}
+
+// StartStreamingHandler runs a response-streaming Lambda handler, reading events from
+// and writing responses to the AWS Lambda Runtime API with
+// Lambda-Runtime-Function-Response-Mode: streaming.
+//
+// The handler's return value may implement ResponseStreamer, io.WriterTo, or io.Reader
+// to stream its body; any other return type falls back to being JSON encoded in full
+// before being written, with encoding/json's default settings: HTML escaping is on,
+// the trailing newline is kept, and WithCodec, WithSetEscapeHTML and WithSetIndent
+// do not apply.
+//
+// Usage:
+//
+//	lambda.StartStreamingHandler(
+//		func(ctx context.Context, event MyEvent) (lambda.ResponseStreamer, error) {
+//			return myStreamer{event}, nil
+//		},
+//	)
+func StartStreamingHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](handlerFunc H, options ...Option) {
+	options = append(options, WithStreamingResponse())
+	h := newHandler(handlerFunc, options...)
+	h.streamingHandlerFunc = wrapStreamingMiddleware(reflectStreamingHandler(handlerFunc, h), h)
+	start(h)
+}
+
+// streamingWriterContextKey carries the StreamingResponseWriter for the current
+// invocation through the middleware chain, since Middleware is defined in terms of
+// Handler's buffered []byte signature and has no other way to reach the writer a
+// streamingHandlerFunc writes to directly.
+type streamingWriterContextKey struct{}
+
+// wrapStreamingMiddleware runs inner through h.middlewares, the same chain
+// WithMiddleware wraps buffered handlers with in newHandler, so middlewares like
+// middleware.Recover() and middleware.Timeout() apply to streaming handlers too
+// instead of silently being a no-op. The adapted Handler's response bytes are always
+// nil: inner writes its response straight to the StreamingResponseWriter rather than
+// returning it, so there's nothing for a middleware to buffer or transform -- every
+// built-in middleware only inspects ctx/payload/err, which still flow through
+// unchanged.
+func wrapStreamingMiddleware(inner streamingHandlerFunc, h *handlerOptions) streamingHandlerFunc {
+	if len(h.middlewares) == 0 {
+		return inner
+	}
+	wrapped := chainMiddleware(handlerFuncBytes(func(ctx context.Context, payload []byte) ([]byte, error) {
+		w, _ := ctx.Value(streamingWriterContextKey{}).(StreamingResponseWriter)
+		return nil, inner(ctx, payload, w)
+	}), h.middlewares)
+	return func(ctx context.Context, payload []byte, w StreamingResponseWriter) error {
+		ctx = context.WithValue(ctx, streamingWriterContextKey{}, w)
+		_, err := wrapped.Invoke(ctx, payload)
+		return err
+	}
+}
+
+// streamingResponseWriter writes straight through to the pipe feeding the outgoing
+// HTTP request body, with no intermediate buffering: each Write hands its bytes to
+// the runtime API as soon as the request's Transport reads them, which is what makes
+// progressive rendering and SSE-style handlers actually stream instead of batching up
+// to a buffer size. ready is closed the moment the response is committed -- on the
+// first Write, or when the handler returns having never written anything -- so the
+// goroutine building the request headers knows contentType is final before it reads
+// it.
+type streamingResponseWriter struct {
+	pw          *io.PipeWriter
+	contentType string
+	ready       chan struct{}
+	readyOnce   sync.Once
+	// wrote records whether Write was ever called, so postStreamingResponse can
+	// tell a handler that failed before producing any output (safe to report via
+	// the plain .../error endpoint) from one that failed mid-stream (already
+	// committed a partial response). Only ever touched from the handler goroutine,
+	// and only read after that goroutine has signalled ready, so no lock is needed.
+	wrote bool
+}
+
+func (s *streamingResponseWriter) SetContentType(contentType string) {
+	s.contentType = contentType
+}
+
+func (s *streamingResponseWriter) Write(p []byte) (int, error) {
+	s.wrote = true
+	s.readyOnce.Do(func() { close(s.ready) })
+	return s.pw.Write(p)
+}
+
+func (s *streamingResponseWriter) markReady() {
+	s.readyOnce.Do(func() { close(s.ready) })
+}
+
+type streamingHandlerFunc func(ctx context.Context, payload []byte, w StreamingResponseWriter) error
+
+// reflectStreamingHandler adapts a typed handler function into code the streaming
+// invoke loop can call: decode the incoming event, invoke the handler, then write the
+// response directly to w rather than buffering it into a jsonOutBuffer.
+func reflectStreamingHandler[TIn any, TOut any, H HandlerFunc[TIn, TOut]](f H, h *handlerOptions) streamingHandlerFunc {
+	if f == nil {
+		return func(_ context.Context, _ []byte, _ StreamingResponseWriter) error {
+			return errors.New("handler is nil")
+		}
+	}
+
+	return func(ctx context.Context, payload []byte, w StreamingResponseWriter) error {
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+
+		trace := handlertrace.FromContext(ctx)
+
+		event := new(TIn)
+		if err := decoder.Decode(event); err != nil {
+			return err
+		}
+		if nil != trace.RequestEvent {
+			trace.RequestEvent(ctx, event)
+		}
+
+		response, err := f(ctx, *event)
+		if err != nil {
+			return err
+		}
+
+		if nil != trace.ResponseEvent {
+			trace.ResponseEvent(ctx, response)
+		}
+
+		return writeStreamingResponse(ctx, response, w)
+	}
+}
+
+// writeStreamingResponse writes response to w, taking the fastest path its concrete
+// type allows: a ResponseStreamer controls its own framing and content type; an
+// io.WriterTo or io.Reader is copied through as-is; anything else is JSON encoded by
+// a default encoding/json Encoder, not by the buffered path's codec and options.
+func writeStreamingResponse(ctx context.Context, response any, w StreamingResponseWriter) error {
+	switch response := response.(type) {
+	case ResponseStreamer:
+		return response.Stream(ctx, w)
+	case io.WriterTo:
+		_, err := response.WriteTo(w)
+		return err
+	case io.Reader:
+		_, err := io.Copy(w, response)
+		return err
+	default:
+		w.SetContentType(contentTypeJSON)
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+// startRuntimeAPIStreamingLoop is the streaming counterpart to the buffered invoke
+// loop: it posts responses with Lambda-Runtime-Function-Response-Mode: streaming and
+// Transfer-Encoding: chunked, so a ResponseStreamer can flush bytes to the runtime API
+// as it produces them instead of waiting for the whole response to be buffered first.
+func startRuntimeAPIStreamingLoop(api string, h *handlerOptions) error {
+	shutdownCtx := ShutdownContext(h.baseContext)
+	client := &http.Client{}
+	for {
+		select {
+		case <-shutdownCtx.Done():
+			// SIGTERM arrived before or during the invocation just handled: stop
+			// pulling new work and drain.
+			runOnShutdown(h)
+			return nil
+		default:
+		}
+
+		requestID, deadline, invokedFunctionArn, payload, err := getNextInvocation(client, api)
+		if err != nil {
+			return fmt.Errorf("failed to get next invocation: %w", err)
+		}
+
+		if err := handleStreamingInvocation(h, client, api, requestID, deadline, invokedFunctionArn, payload); err != nil {
+			return err
+		}
+	}
+}
+
+// handleStreamingInvocation runs a single invocation in its own call frame so the
+// deadline context's cancel func, if any, runs as soon as the invocation finishes
+// instead of piling up on startRuntimeAPIStreamingLoop's defer stack for the life of
+// the warm container.
+func handleStreamingInvocation(h *handlerOptions, client *http.Client, api, requestID string, deadline *time.Time, invokedFunctionArn string, payload []byte) error {
+	ctx := h.baseContext
+	if deadline != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, *deadline)
+		defer cancel()
+	}
+	ctx = newInvocationContext(ctx, requestID, invokedFunctionArn)
+
+	if invokeErr := postStreamingResponse(ctx, client, api, requestID, h.streamingHandlerFunc, payload); invokeErr != nil {
+		if err := postStreamingInvocationError(client, api, requestID, invokeErr); err != nil {
+			return fmt.Errorf("failed to post invocation error: %w", err)
+		}
+	}
+	return nil
+}
+
+func getNextInvocation(client *http.Client, api string) (requestID string, deadline *time.Time, invokedFunctionArn string, payload []byte, err error) {
+	req, err := http.NewRequest(http.MethodGet, "http://"+api+invocationNextPath, nil)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+
+	requestID = resp.Header.Get(headerAwsRequestID)
+	invokedFunctionArn = resp.Header.Get(headerInvokedFunctionArn)
+	if ms := resp.Header.Get(headerDeadlineMs); ms != "" {
+		if msInt, parseErr := strconv.ParseInt(ms, 10, 64); parseErr == nil {
+			t := time.UnixMilli(msInt)
+			deadline = &t
+		}
+	}
+	return requestID, deadline, invokedFunctionArn, body, nil
+}
+
+// postStreamingResponse streams handler's output to the runtime API as it writes it.
+// If handler fails before writing anything, that error is simply returned so the
+// caller can report it through postStreamingInvocationError exactly like a buffered
+// handler's error, since nothing has been sent to the runtime yet for this request.
+// If handler fails after writing some bytes, the chunked response has already been
+// committed: there is no trailing-error framing implemented to surface handlerErr on
+// the wire once that's happened (scoped out of this change), so it's logged instead
+// of being returned, to avoid a second, conflicting post to the same request ID.
+func postStreamingResponse(ctx context.Context, client *http.Client, api, requestID string, handler streamingHandlerFunc, payload []byte) error {
+	pr, pw := io.Pipe()
+	writer := &streamingResponseWriter{pw: pw, ready: make(chan struct{})}
+
+	errCh := make(chan error, 1)
+	go func() {
+		err := handler(ctx, payload, writer)
+		// the handler may have returned without ever calling Write (e.g. an error
+		// before producing any output); mark ready regardless so the request isn't
+		// built against a half-finished contentType.
+		writer.markReady()
+		if err != nil && !writer.wrote {
+			pw.CloseWithError(err)
+		} else {
+			// either success, or a partial response has already been written: end
+			// the chunked body cleanly so the already-sent bytes are delivered
+			// rather than surfacing as a transport-level write error.
+			pw.Close()
+		}
+		errCh <- err
+	}()
+
+	// wait for the handler to either write its first byte or finish, so
+	// writer.contentType (set via SetContentType before the first Write, per its
+	// doc) is settled before we read it below.
+	<-writer.ready
+
+	if !writer.wrote {
+		// nothing has been written, so the handler has returned. On error pr is
+		// already closed with handlerErr: don't race client.Do against it, since
+		// that would just surface a generic "read/write on closed pipe" transport
+		// error in place of the real one. On success pr is closed cleanly, so an
+		// empty response is posted below.
+		if handlerErr := <-errCh; handlerErr != nil {
+			return handlerErr
+		}
+		errCh <- nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+api+fmt.Sprintf(invocationRespPath, requestID), pr)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headerResponseMode, responseModeStreaming)
+	req.Header.Set("Transfer-Encoding", "chunked")
+	if writer.contentType != "" {
+		req.Header.Set("Content-Type", writer.contentType)
+	}
+
+	resp, err := client.Do(req)
+	handlerErr := <-errCh
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if handlerErr != nil {
+		log.Printf("streaming handler for request %s failed after writing a partial response: %v", requestID, handlerErr)
+	}
+	return nil
+}
+
+func postStreamingInvocationError(client *http.Client, api, requestID string, invokeErr error) error {
+	body, err := json.Marshal(struct {
+		ErrorMessage string `json:"errorMessage"`
+		ErrorType    string `json:"errorType"`
+	}{ErrorMessage: invokeErr.Error(), ErrorType: "HandlerStreamingError"})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+api+fmt.Sprintf(invocationErrorPath, requestID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+package lambda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostStreamingResponseErrorBeforeWrite(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	api := strings.TrimPrefix(srv.URL, "http://")
+
+	wantErr := errors.New("boom before any output")
+	handler := streamingHandlerFunc(func(ctx context.Context, payload []byte, w StreamingResponseWriter) error {
+		return wantErr
+	})
+
+	err := postStreamingResponse(context.Background(), &http.Client{}, api, "req-1", handler, nil)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("postStreamingResponse() error = %v, want %v", err, wantErr)
+	}
+	if called {
+		t.Error("postStreamingResponse() should not have posted a response when the handler never wrote anything")
+	}
+}
+
+func TestPostStreamingResponseErrorAfterWrite(t *testing.T) {
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := make([]byte, 4096)
+		n, _ := r.Body.Read(b)
+		gotBody = b[:n]
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	api := strings.TrimPrefix(srv.URL, "http://")
+
+	handler := streamingHandlerFunc(func(ctx context.Context, payload []byte, w StreamingResponseWriter) error {
+		w.SetContentType("text/plain")
+		if _, err := w.Write([]byte("partial-data")); err != nil {
+			return err
+		}
+		return errors.New("boom mid stream")
+	})
+
+	err := postStreamingResponse(context.Background(), &http.Client{}, api, "req-1", handler, nil)
+	if err != nil {
+		t.Fatalf("postStreamingResponse() = %v, want nil once the response has already been committed", err)
+	}
+	if string(gotBody) != "partial-data" {
+		t.Errorf("got body %q, want %q", gotBody, "partial-data")
+	}
+}
+
+// TestHandleStreamingInvocationReleasesDeadlineContextPromptly mirrors
+// TestHandleInvocationReleasesDeadlineContextPromptly in invoke_test.go for the
+// streaming loop: the deadline context's cancel func must run at the end of each
+// invocation, not accumulate on startRuntimeAPIStreamingLoop's defer stack for the
+// life of the warm container.
+func TestHandleStreamingInvocationReleasesDeadlineContextPromptly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	api := strings.TrimPrefix(srv.URL, "http://")
+
+	var gotCtx context.Context
+	h := &handlerOptions{
+		baseContext: context.Background(),
+		streamingHandlerFunc: streamingHandlerFunc(func(ctx context.Context, payload []byte, w StreamingResponseWriter) error {
+			gotCtx = ctx
+			return nil
+		}),
+	}
+
+	deadline := time.Now().Add(time.Minute)
+	if err := handleStreamingInvocation(h, &http.Client{}, api, "req-1", &deadline, "arn", nil); err != nil {
+		t.Fatalf("handleStreamingInvocation() returned error: %v", err)
+	}
+
+	if gotCtx.Err() != context.Canceled {
+		t.Errorf("ctx.Err() = %v once handleStreamingInvocation returned, want context.Canceled", gotCtx.Err())
+	}
+}